@@ -0,0 +1,185 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+)
+
+// BatchTransferLeg is one leg of a multi-leg transfer: Amount of Currency
+// moving from FromAccountID to ToAccountID.
+type BatchTransferLeg struct {
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+}
+
+// BatchTransferParams is the set of legs TransferBatchTx must settle
+// atomically: either every leg commits, or none do.
+type BatchTransferParams struct {
+	Legs []BatchTransferLeg `json:"legs"`
+}
+
+// AccountNetEntry is the net balance delta applied to a single account as
+// part of settling a batch, after summing every leg that touched it.
+type AccountNetEntry struct {
+	Account   Account `json:"account"`
+	NetAmount int64   `json:"net_amount"`
+}
+
+// BatchTransferResult is the outcome of a TransferBatchTx call.
+type BatchTransferResult struct {
+	Batch      TransferBatch     `json:"batch"`
+	Transfers  []Transfer        `json:"transfers"`
+	NetEntries []AccountNetEntry `json:"net_entries"`
+}
+
+// TransferBatchTx atomically settles a slice of transfer legs. Accounts
+// touched by more than one leg are locked once, in ascending ID order
+// (reusing the deadlock-avoidance approach updateAccountBalances already
+// uses for single transfers), and each account's net debit is checked
+// in-memory against its locked balance before any balance is written. This
+// lets callers express fee splits, multi-currency FX legs, and payroll
+// disbursements that a one-to-one TransferTx can't.
+//
+// The restriction chain runs twice: once per leg, so pairwise checks like
+// CurrencyMismatchRestriction and FrozenAccountRestriction see the actual
+// counterparty on each leg, and once more per net-debited account after all
+// legs are summed, passing that account's total net debit for the whole
+// batch as amount (with to == from, since a cumulative check like
+// DailyTransferCapRestriction only cares about the sender). Without the
+// second pass, two legs debiting the same account would each be checked
+// against the cap in isolation and could jointly exceed it.
+func (store *SQLStore) TransferBatchTx(ctx context.Context, arg BatchTransferParams) (BatchTransferResult, error) {
+	if err := validateBatchTransferParams(arg); err != nil {
+		return BatchTransferResult{}, err
+	}
+
+	var result BatchTransferResult
+	accountIDs := distinctSortedAccountIDs(arg.Legs)
+	locked := make(map[int64]Account, len(accountIDs))
+	net := make(map[int64]int64, len(accountIDs))
+
+	txOpts := &sql.TxOptions{Isolation: sql.LevelSerializable}
+	err := store.execTx(ctx, txOpts, func(q *Queries) error {
+		// A retried attempt starts from a fresh transaction, so start the
+		// per-account sums over as well.
+		net = make(map[int64]int64, len(accountIDs))
+		for _, id := range accountIDs {
+			account, err := q.GetAccountForUpdate(ctx, id)
+			if err != nil {
+				return err
+			}
+			locked[id] = account
+		}
+
+		for _, leg := range arg.Legs {
+			from, to := locked[leg.FromAccountID], locked[leg.ToAccountID]
+			if from.Currency != leg.Currency || to.Currency != leg.Currency {
+				return fmt.Errorf("currency mismatch on leg %d->%d: leg is %s, accounts are %s/%s",
+					leg.FromAccountID, leg.ToAccountID, leg.Currency, from.Currency, to.Currency)
+			}
+			if err := store.runSendRestrictions(ctx, from, to, leg.Amount, leg.Currency); err != nil {
+				return err
+			}
+			net[leg.FromAccountID] -= leg.Amount
+			net[leg.ToAccountID] += leg.Amount
+		}
+
+		for _, id := range accountIDs {
+			if net[id] >= 0 {
+				continue
+			}
+			account := locked[id]
+			if err := store.runSendRestrictions(ctx, account, account, -net[id], account.Currency); err != nil {
+				return err
+			}
+		}
+
+		for _, id := range accountIDs {
+			if delta := net[id]; delta < 0 && locked[id].Balance+delta < 0 {
+				return fmt.Errorf("insufficient balance: account %d has %d, net debit %d", id, locked[id].Balance, -delta)
+			}
+		}
+
+		batch, err := q.CreateTransferBatch(ctx)
+		if err != nil {
+			return err
+		}
+		result.Batch = batch
+
+		result.Transfers = make([]Transfer, 0, len(arg.Legs))
+		for _, leg := range arg.Legs {
+			transfer, err := q.CreateBatchTransfer(ctx, CreateBatchTransferParams{
+				FromAccountID: leg.FromAccountID,
+				ToAccountID:   leg.ToAccountID,
+				Amount:        leg.Amount,
+				BatchID:       sql.NullInt64{Int64: batch.ID, Valid: true},
+			})
+			if err != nil {
+				return err
+			}
+			result.Transfers = append(result.Transfers, transfer)
+
+			if _, err := q.CreateEntry(ctx, CreateEntryParams{AccountID: leg.FromAccountID, Amount: -leg.Amount}); err != nil {
+				return err
+			}
+			if _, err := q.CreateEntry(ctx, CreateEntryParams{AccountID: leg.ToAccountID, Amount: leg.Amount}); err != nil {
+				return err
+			}
+		}
+
+		result.NetEntries = make([]AccountNetEntry, 0, len(accountIDs))
+		for _, id := range accountIDs {
+			account, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{ID: id, Amount: net[id]})
+			if err != nil {
+				return err
+			}
+			result.NetEntries = append(result.NetEntries, AccountNetEntry{Account: account, NetAmount: net[id]})
+		}
+
+		return nil
+	}, func(q *Queries) error {
+		checks := make([]ledgerCheck, 0, len(accountIDs))
+		for _, id := range accountIDs {
+			checks = append(checks, ledgerCheck{accountID: id, priorBalance: locked[id].Balance, entriesDelta: net[id]})
+		}
+		return verifyLedgerInvariant(ctx, q, checks)
+	})
+
+	return result, err
+}
+
+func validateBatchTransferParams(arg BatchTransferParams) error {
+	if len(arg.Legs) == 0 {
+		return fmt.Errorf("batch must contain at least one leg")
+	}
+	for _, leg := range arg.Legs {
+		if leg.FromAccountID == leg.ToAccountID {
+			return fmt.Errorf("cannot transfer to the same account")
+		}
+		if leg.Amount <= 0 {
+			return fmt.Errorf("amount must be positive")
+		}
+	}
+	return nil
+}
+
+// distinctSortedAccountIDs returns every account ID touched by legs exactly
+// once, in ascending order, so callers can lock them consistently.
+func distinctSortedAccountIDs(legs []BatchTransferLeg) []int64 {
+	seen := make(map[int64]struct{})
+	ids := make([]int64, 0, len(legs)*2)
+	for _, leg := range legs {
+		for _, id := range [2]int64{leg.FromAccountID, leg.ToAccountID} {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDistinctSortedAccountIDs(t *testing.T) {
+	legs := []BatchTransferLeg{
+		{FromAccountID: 3, ToAccountID: 1, Amount: 10, Currency: "USD"},
+		{FromAccountID: 1, ToAccountID: 2, Amount: 5, Currency: "USD"},
+		{FromAccountID: 3, ToAccountID: 2, Amount: 7, Currency: "USD"},
+	}
+
+	require.Equal(t, []int64{1, 2, 3}, distinctSortedAccountIDs(legs))
+}
+
+func TestDistinctSortedAccountIDsEmpty(t *testing.T) {
+	require.Empty(t, distinctSortedAccountIDs(nil))
+}
+
+func TestValidateBatchTransferParams(t *testing.T) {
+	testCases := []struct {
+		name    string
+		arg     BatchTransferParams
+		wantErr bool
+	}{
+		{
+			name: "valid single leg",
+			arg: BatchTransferParams{Legs: []BatchTransferLeg{
+				{FromAccountID: 1, ToAccountID: 2, Amount: 10, Currency: "USD"},
+			}},
+		},
+		{
+			name:    "no legs",
+			arg:     BatchTransferParams{},
+			wantErr: true,
+		},
+		{
+			name: "same account",
+			arg: BatchTransferParams{Legs: []BatchTransferLeg{
+				{FromAccountID: 1, ToAccountID: 1, Amount: 10, Currency: "USD"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "non-positive amount",
+			arg: BatchTransferParams{Legs: []BatchTransferLeg{
+				{FromAccountID: 1, ToAccountID: 2, Amount: 0, Currency: "USD"},
+			}},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateBatchTransferParams(tc.arg)
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
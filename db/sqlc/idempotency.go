@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// idempotencyKeyTTL is how long an idempotency key is honored after it is
+// first used. Requests replayed after this window creates a new transfer.
+const idempotencyKeyTTL = 24 * time.Hour
+
+// ErrIdempotencyConflict is returned when a caller reuses an idempotency key
+// with request parameters that don't match the ones it was first used with.
+var ErrIdempotencyConflict = errors.New("idempotency key reused with different request parameters")
+
+// loadIdempotentTransfer looks up arg.IdempotencyKey inside the current
+// transaction. It returns a non-nil result if the key was already used for
+// an identical request, ErrIdempotencyConflict if it was used for a
+// different request, or (nil, nil) if the key is unseen.
+func loadIdempotentTransfer(ctx context.Context, q *Queries, arg TransferTxParams) (*TransferTxResult, error) {
+	existing, err := q.GetIdempotencyKeyForUpdate(ctx, arg.IdempotencyKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.ExpiresAt.Before(time.Now()) {
+		// The key has expired: treat it as unseen so the caller gets a fresh
+		// transfer, and drop the stale row so it can be reused.
+		if err := q.DeleteIdempotencyKey(ctx, arg.IdempotencyKey); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	if existing.RequestHash != transferRequestHash(arg) {
+		return nil, ErrIdempotencyConflict
+	}
+
+	var cached TransferTxResult
+	if err := json.Unmarshal([]byte(existing.ResponseJSON.String), &cached); err != nil {
+		return nil, fmt.Errorf("unmarshal cached transfer result: %w", err)
+	}
+	return &cached, nil
+}
+
+// storeIdempotentTransfer persists arg.IdempotencyKey alongside the
+// serialized result, so a replay of the same request returns it instead of
+// creating a second transfer.
+func storeIdempotentTransfer(ctx context.Context, q *Queries, arg TransferTxParams, result TransferTxResult) error {
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshal transfer result: %w", err)
+	}
+
+	_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+		Key:          arg.IdempotencyKey,
+		RequestHash:  transferRequestHash(arg),
+		TransferID:   sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+		ResponseJSON: sql.NullString{String: string(payload), Valid: true},
+		ExpiresAt:    time.Now().Add(idempotencyKeyTTL),
+	})
+	return err
+}
+
+// transferRequestHash hashes the parts of arg that must match for a replayed
+// request to be considered the same transfer.
+func transferRequestHash(arg TransferTxParams) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%d:%d", arg.FromAccountID, arg.ToAccountID, arg.Amount)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,22 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferRequestHash(t *testing.T) {
+	base := TransferTxParams{FromAccountID: 1, ToAccountID: 2, Amount: 100}
+
+	require.Equal(t, transferRequestHash(base), transferRequestHash(base), "same params must hash the same")
+
+	variants := []TransferTxParams{
+		{FromAccountID: 2, ToAccountID: 2, Amount: 100},
+		{FromAccountID: 1, ToAccountID: 3, Amount: 100},
+		{FromAccountID: 1, ToAccountID: 2, Amount: 101},
+	}
+	for _, v := range variants {
+		require.NotEqual(t, transferRequestHash(base), transferRequestHash(v), "%+v must hash differently than %+v", v, base)
+	}
+}
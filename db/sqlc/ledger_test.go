@@ -0,0 +1,41 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedgerCheckSatisfiedBy(t *testing.T) {
+	testCases := []struct {
+		name           string
+		check          ledgerCheck
+		currentBalance int64
+		want           bool
+	}{
+		{
+			name:           "balance moved by exactly the entries delta",
+			check:          ledgerCheck{accountID: 1, priorBalance: 100, entriesDelta: -30},
+			currentBalance: 70,
+			want:           true,
+		},
+		{
+			name:           "balance unchanged but entries expected a delta",
+			check:          ledgerCheck{accountID: 1, priorBalance: 100, entriesDelta: -30},
+			currentBalance: 100,
+			want:           false,
+		},
+		{
+			name:           "nonzero seed balance with a zero-delta check still matches",
+			check:          ledgerCheck{accountID: 1, priorBalance: 5000, entriesDelta: 0},
+			currentBalance: 5000,
+			want:           true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.check.satisfiedBy(tc.currentBalance))
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// OutboxEvent is a domain event to be delivered to downstream systems once
+// the transaction that produced it commits.
+type OutboxEvent struct {
+	Type    string      `json:"type"`
+	Payload interface{} `json:"payload"`
+}
+
+// WithOutbox inserts event into outbox_events as part of the caller's
+// transaction, so the event row commits atomically with the change that
+// produced it. Call it from inside an execTx/ExecTx closure.
+func WithOutbox(ctx context.Context, q *Queries, event OutboxEvent) error {
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return fmt.Errorf("marshal outbox event payload: %w", err)
+	}
+
+	_, err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		EventType: event.Type,
+		Payload:   payload,
+	})
+	return err
+}
+
+// DecodeOutboxEvent turns a stored row back into the OutboxEvent an
+// OutboxDispatcher hands to a Publisher.
+func DecodeOutboxEvent(row OutboxEventRow) (OutboxEvent, error) {
+	var payload interface{}
+	if err := json.Unmarshal(row.Payload, &payload); err != nil {
+		return OutboxEvent{}, fmt.Errorf("unmarshal outbox event payload: %w", err)
+	}
+	return OutboxEvent{Type: row.EventType, Payload: payload}, nil
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// CurrencyMismatchRestriction rejects transfers where the transfer currency
+// doesn't match both the sending and receiving account's currency.
+var CurrencyMismatchRestriction = NamedSendRestriction("currency_mismatch", func(ctx context.Context, from, to Account, amount int64, currency string) error {
+	if from.Currency != currency || to.Currency != currency {
+		return fmt.Errorf("currency mismatch: transfer is %s, from account is %s, to account is %s", currency, from.Currency, to.Currency)
+	}
+	return nil
+})
+
+// AccountFlagFn reports a boolean property of an account, e.g. whether it's
+// frozen. It's injected rather than read off Account directly so built-in
+// restrictions don't depend on columns the core account model may not have.
+type AccountFlagFn func(ctx context.Context, accountID int64) (bool, error)
+
+// FrozenAccountRestriction rejects a transfer if either account is flagged
+// by isFrozen, e.g. because compliance froze it pending review.
+func FrozenAccountRestriction(isFrozen AccountFlagFn) SendRestrictionFn {
+	return NamedSendRestriction("frozen_account", func(ctx context.Context, from, to Account, amount int64, currency string) error {
+		frozen, err := isFrozen(ctx, from.ID)
+		if err != nil {
+			return err
+		}
+		if frozen {
+			return fmt.Errorf("account %d is frozen", from.ID)
+		}
+
+		frozen, err = isFrozen(ctx, to.ID)
+		if err != nil {
+			return err
+		}
+		if frozen {
+			return fmt.Errorf("account %d is frozen", to.ID)
+		}
+		return nil
+	})
+}
+
+// AccountTotalFn reports a rolling total for an account, e.g. everything it
+// has sent in the current day.
+type AccountTotalFn func(ctx context.Context, accountID int64) (int64, error)
+
+// DailyTransferCapRestriction rejects a transfer once the sending account's
+// rolling total for the day, including this transfer, would exceed cap.
+// A cap of 0 disables the check for that account.
+func DailyTransferCapRestriction(dailySentTotal AccountTotalFn, cap AccountTotalFn) SendRestrictionFn {
+	return NamedSendRestriction("daily_transfer_cap", func(ctx context.Context, from, to Account, amount int64, currency string) error {
+		limit, err := cap(ctx, from.ID)
+		if err != nil {
+			return err
+		}
+		if limit <= 0 {
+			return nil
+		}
+
+		sent, err := dailySentTotal(ctx, from.ID)
+		if err != nil {
+			return err
+		}
+		if sent+amount > limit {
+			return fmt.Errorf("account %d daily transfer cap %d exceeded by %d", from.ID, limit, sent+amount-limit)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,32 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRestrictionBypassed(t *testing.T) {
+	ctx := context.WithValue(context.Background(), BypassRestrictionsKey, []string{"frozen_account", "daily_transfer_cap"})
+
+	require.True(t, isRestrictionBypassed(ctx, "frozen_account"))
+	require.True(t, isRestrictionBypassed(ctx, "daily_transfer_cap"))
+	require.False(t, isRestrictionBypassed(ctx, "currency_mismatch"))
+}
+
+func TestIsRestrictionBypassedNoValue(t *testing.T) {
+	require.False(t, isRestrictionBypassed(context.Background(), "frozen_account"))
+}
+
+func TestNamedSendRestrictionHonorsBypass(t *testing.T) {
+	called := false
+	restriction := NamedSendRestriction("frozen_account", func(ctx context.Context, from, to Account, amount int64, currency string) error {
+		called = true
+		return nil
+	})
+
+	ctx := context.WithValue(context.Background(), BypassRestrictionsKey, []string{"frozen_account"})
+	require.NoError(t, restriction(ctx, Account{}, Account{}, 100, "USD"))
+	require.False(t, called, "bypassed restriction must not invoke the wrapped fn")
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PostgreSQL SQLSTATE codes worth retrying a whole transaction for: a
+// serialization failure under SERIALIZABLE isolation, and a deadlock the
+// driver broke by aborting one of the participants.
+const (
+	pqSerializationFailure = "40001"
+	pqDeadlockDetected     = "40P01"
+)
+
+// ErrTxRetryExhausted wraps the last error seen after a transaction ran out
+// of retry attempts for a retryable failure.
+var ErrTxRetryExhausted = errors.New("transaction retry attempts exhausted")
+
+// TxRetryConfig controls how execTxWithRetry retries a transaction closure
+// on serialization failures and deadlocks.
+type TxRetryConfig struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+var defaultTxRetryConfig = TxRetryConfig{
+	MaxAttempts: 5,
+	BaseDelay:   20 * time.Millisecond,
+	MaxDelay:    500 * time.Millisecond,
+}
+
+// execTxWithRetry re-runs fn (and, if set, verify) on a fresh transaction
+// whenever the driver reports a serialization failure or deadlock, applying
+// exponential backoff with jitter between attempts. It gives up as soon as
+// ctx is done or cfg.MaxAttempts is reached.
+func (store *SQLStore) execTxWithRetry(ctx context.Context, opts *sql.TxOptions, cfg TxRetryConfig, fn func(*Queries) error, verify func(*Queries) error) error {
+	var lastErr error
+
+	for attempt := 0; attempt < cfg.MaxAttempts; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := store.runTx(ctx, opts, fn, verify)
+		if err == nil {
+			return nil
+		}
+		if !isRetryableTxError(err) {
+			return err
+		}
+		lastErr = err
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(txBackoff(cfg, attempt)):
+		}
+	}
+
+	return fmt.Errorf("%w: %w", ErrTxRetryExhausted, lastErr)
+}
+
+func isRetryableTxError(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case pqSerializationFailure, pqDeadlockDetected:
+			return true
+		}
+	}
+	return false
+}
+
+// txBackoff returns exponential backoff with full jitter, capped at
+// cfg.MaxDelay.
+func txBackoff(cfg TxRetryConfig, attempt int) time.Duration {
+	delay := cfg.BaseDelay * time.Duration(1<<uint(attempt))
+	if delay <= 0 || delay > cfg.MaxDelay {
+		delay = cfg.MaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
@@ -0,0 +1,30 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxBackoffBounds(t *testing.T) {
+	cfg := TxRetryConfig{MaxAttempts: 5, BaseDelay: 20 * time.Millisecond, MaxDelay: 500 * time.Millisecond}
+
+	for attempt := 0; attempt < cfg.MaxAttempts; attempt++ {
+		for i := 0; i < 50; i++ {
+			delay := txBackoff(cfg, attempt)
+			require.GreaterOrEqual(t, delay, time.Duration(0))
+			require.LessOrEqual(t, delay, cfg.MaxDelay)
+		}
+	}
+}
+
+func TestTxBackoffCapsAtMaxDelay(t *testing.T) {
+	cfg := TxRetryConfig{MaxAttempts: 20, BaseDelay: 20 * time.Millisecond, MaxDelay: 500 * time.Millisecond}
+
+	// At a high enough attempt count, exponential growth overflows past
+	// MaxDelay and txBackoff must fall back to capping at MaxDelay.
+	for i := 0; i < 50; i++ {
+		require.LessOrEqual(t, txBackoff(cfg, 19), cfg.MaxDelay)
+	}
+}
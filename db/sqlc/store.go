@@ -4,39 +4,91 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sync"
 )
 
 type Store interface {
 	Querier
+	ExecTx(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	TransferBatchTx(ctx context.Context, arg BatchTransferParams) (BatchTransferResult, error)
+	AppendSendRestriction(fn SendRestrictionFn)
+	WithRetryConfig(cfg TxRetryConfig) Store
 }
 
 type SQLStore struct {
 	*Queries
 	db *sql.DB
+
+	retryConfigMu sync.RWMutex
+	retryConfig   TxRetryConfig
+
+	sendRestrictionsMu sync.Mutex
+	sendRestrictions   []SendRestrictionFn
 }
 
 func NewStore(db *sql.DB) Store {
 	return &SQLStore{
-		db:      db,
-		Queries: New(db),
+		db:          db,
+		Queries:     New(db),
+		retryConfig: defaultTxRetryConfig,
 	}
 }
 
-func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, nil)
+// WithRetryConfig overrides the TxRetryConfig execTx uses for this Store,
+// e.g. to raise MaxAttempts for a batch job or shorten BaseDelay in tests.
+// It mutates and returns the same Store so callers can chain it off
+// NewStore.
+func (store *SQLStore) WithRetryConfig(cfg TxRetryConfig) Store {
+	store.retryConfigMu.Lock()
+	defer store.retryConfigMu.Unlock()
+	store.retryConfig = cfg
+	return store
+}
+
+// ExecTx runs fn inside a database transaction opened with opts (pass nil
+// for driver defaults), retrying the whole closure with backoff if the
+// driver reports a serialization failure or deadlock. Callers that need a
+// stronger isolation level than the default, e.g. sql.LevelSerializable for
+// TransferTx, set it on opts.
+func (store *SQLStore) ExecTx(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
+	return store.execTx(ctx, opts, fn, nil)
+}
+
+// execTx is the internal entry point shared by ExecTx and by transaction
+// types that must enforce invariants before commit: a non-nil verify runs
+// on the same transaction after fn succeeds, and an error from it rolls the
+// transaction back.
+func (store *SQLStore) execTx(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error, verify func(*Queries) error) error {
+	store.retryConfigMu.RLock()
+	cfg := store.retryConfig
+	store.retryConfigMu.RUnlock()
+	return store.execTxWithRetry(ctx, opts, cfg, fn, verify)
+}
+
+func (store *SQLStore) runTx(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error, verify func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
 	q := New(tx)
 
-	err = fn(q)
-	if err != nil {
+	if err := fn(q); err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
 			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
 		}
 		return err
 	}
+
+	if verify != nil {
+		if err := verify(q); err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				return fmt.Errorf("verify err: %v, rb err: %v", err, rbErr)
+			}
+			return err
+		}
+	}
+
 	return tx.Commit()
 }
 
@@ -44,6 +96,11 @@ type TransferTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+
+	// IdempotencyKey, if set, makes repeated calls with the same key and
+	// request parameters return the original result instead of creating a
+	// second transfer. Safe to leave empty for callers that don't retry.
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
 }
 
 type TransferTxResult struct {
@@ -61,12 +118,31 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (Tr
 	}
 
 	var result TransferTxResult
+	var fromBalanceBefore, toBalanceBefore int64
+	skipVerify := false
 
-	err := store.execTx(ctx, func(q *Queries) error {
+	txOpts := &sql.TxOptions{Isolation: sql.LevelSerializable}
+	err := store.execTx(ctx, txOpts, func(q *Queries) error {
 		var err error
 
+		if arg.IdempotencyKey != "" {
+			cached, err := loadIdempotentTransfer(ctx, q, arg)
+			if err != nil {
+				return err
+			}
+			if cached != nil {
+				result = *cached
+				skipVerify = true
+				return nil
+			}
+		}
+
 		// Create transfer record
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams(arg))
+		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
+		})
 		if err != nil {
 			return err
 		}
@@ -89,8 +165,27 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (Tr
 		}
 
 		// Update account balances with proper locking order
-		result.FromAccount, result.ToAccount, err = updateAccountBalances(ctx, q, arg)
-		return err
+		fromBalanceBefore, toBalanceBefore, result.FromAccount, result.ToAccount, err = store.updateAccountBalances(ctx, q, arg)
+		if err != nil {
+			return err
+		}
+
+		if err := WithOutbox(ctx, q, OutboxEvent{Type: "transfer.completed", Payload: result}); err != nil {
+			return err
+		}
+
+		if arg.IdempotencyKey != "" {
+			return storeIdempotentTransfer(ctx, q, arg, result)
+		}
+		return nil
+	}, func(q *Queries) error {
+		if skipVerify {
+			return nil
+		}
+		return verifyLedgerInvariant(ctx, q, []ledgerCheck{
+			{accountID: arg.FromAccountID, priorBalance: fromBalanceBefore, entriesDelta: -arg.Amount},
+			{accountID: arg.ToAccountID, priorBalance: toBalanceBefore, entriesDelta: arg.Amount},
+		})
 	})
 
 	return result, err
@@ -107,8 +202,11 @@ func validateTransferParams(arg TransferTxParams) error {
 	return nil
 }
 
-// updateAccountBalances updates account balances with proper locking order to prevent deadlocks
-func updateAccountBalances(ctx context.Context, q *Queries, arg TransferTxParams) (Account, Account, error) {
+// updateAccountBalances updates account balances with proper locking order to prevent deadlocks.
+// It returns the from/to balances as locked (before this transfer's delta is applied) followed by
+// the from/to accounts after the update, so callers can verify the balance actually moved by the
+// amount the entries recorded.
+func (store *SQLStore) updateAccountBalances(ctx context.Context, q *Queries, arg TransferTxParams) (fromBalanceBefore, toBalanceBefore int64, fromAccount, toAccount Account, err error) {
 	// Determine lock order based on account IDs to prevent deadlocks
 	firstAccountID, secondAccountID := arg.FromAccountID, arg.ToAccountID
 	if firstAccountID > secondAccountID {
@@ -118,40 +216,48 @@ func updateAccountBalances(ctx context.Context, q *Queries, arg TransferTxParams
 	// Lock accounts in consistent order
 	firstAccount, err := q.GetAccountForUpdate(ctx, firstAccountID)
 	if err != nil {
-		return Account{}, Account{}, err
+		return 0, 0, Account{}, Account{}, err
 	}
 
 	secondAccount, err := q.GetAccountForUpdate(ctx, secondAccountID)
 	if err != nil {
-		return Account{}, Account{}, err
+		return 0, 0, Account{}, Account{}, err
 	}
 
 	// Check if from account has sufficient balance
 	if firstAccountID == arg.FromAccountID && firstAccount.Balance < arg.Amount {
-		return Account{}, Account{}, fmt.Errorf("insufficient balance: account %d has %d, trying to transfer %d",
+		return 0, 0, Account{}, Account{}, fmt.Errorf("insufficient balance: account %d has %d, trying to transfer %d",
 			arg.FromAccountID, firstAccount.Balance, arg.Amount)
 	}
 	if secondAccountID == arg.FromAccountID && secondAccount.Balance < arg.Amount {
-		return Account{}, Account{}, fmt.Errorf("insufficient balance: account %d has %d, trying to transfer %d",
+		return 0, 0, Account{}, Account{}, fmt.Errorf("insufficient balance: account %d has %d, trying to transfer %d",
 			arg.FromAccountID, secondAccount.Balance, arg.Amount)
 	}
 
+	fromAccountLocked, toAccountLocked := firstAccount, secondAccount
+	if firstAccountID != arg.FromAccountID {
+		fromAccountLocked, toAccountLocked = secondAccount, firstAccount
+	}
+	if err := store.runSendRestrictions(ctx, fromAccountLocked, toAccountLocked, arg.Amount, fromAccountLocked.Currency); err != nil {
+		return 0, 0, Account{}, Account{}, err
+	}
+
 	// Update balances using AddAccountBalance
-	fromAccount, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{
+	fromAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
 		ID:     arg.FromAccountID,
 		Amount: -arg.Amount,
 	})
 	if err != nil {
-		return Account{}, Account{}, err
+		return 0, 0, Account{}, Account{}, err
 	}
 
-	toAccount, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{
+	toAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
 		ID:     arg.ToAccountID,
 		Amount: arg.Amount,
 	})
 	if err != nil {
-		return Account{}, Account{}, err
+		return 0, 0, Account{}, Account{}, err
 	}
 
-	return fromAccount, toAccount, nil
+	return fromAccountLocked.Balance, toAccountLocked.Balance, fromAccount, toAccount, nil
 }
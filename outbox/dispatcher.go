@@ -0,0 +1,134 @@
+// Package outbox delivers events written by db.WithOutbox to downstream
+// systems (email notifications, analytics, ledgers) with at-least-once
+// guarantees, even if the app crashes between commit and publish.
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	db "simplebank.apiimperfect.com/db/sqlc"
+)
+
+// DefaultMaxAttempts is how many times in total Dispatcher tries to publish
+// a failing event before giving up on it and marking it dead-lettered.
+const DefaultMaxAttempts = 5
+
+// Publisher delivers a single outbox event to a downstream system. Delivery
+// must be idempotent on the receiving side: Dispatcher guarantees
+// at-least-once delivery, not exactly-once.
+type Publisher interface {
+	Publish(ctx context.Context, event db.OutboxEvent) error
+}
+
+// Dispatcher polls outbox_events for rows that haven't been published yet
+// and hands each to a Publisher in its own transaction, so one event that
+// keeps failing its Publish call is retried (and eventually dead-lettered)
+// without blocking or rolling back the rest of the batch.
+type Dispatcher struct {
+	store       db.Store
+	publisher   Publisher
+	interval    time.Duration
+	batchSize   int32
+	maxAttempts int32
+}
+
+// NewDispatcher builds a Dispatcher that polls every interval for up to
+// batchSize unpublished events at a time, trying each up to
+// DefaultMaxAttempts times in total before dead-lettering it.
+func NewDispatcher(store db.Store, publisher Publisher, interval time.Duration, batchSize int32) *Dispatcher {
+	return &Dispatcher{
+		store:       store,
+		publisher:   publisher,
+		interval:    interval,
+		batchSize:   batchSize,
+		maxAttempts: DefaultMaxAttempts,
+	}
+}
+
+// WithMaxAttempts overrides DefaultMaxAttempts.
+func (d *Dispatcher) WithMaxAttempts(maxAttempts int32) *Dispatcher {
+	d.maxAttempts = maxAttempts
+	return d
+}
+
+// Run polls until ctx is cancelled. A failed poll is logged and retried on
+// the next tick rather than crashing the dispatcher.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.dispatchOnce(ctx); err != nil {
+				log.Printf("outbox dispatcher: %v", err)
+			}
+		}
+	}
+}
+
+// dispatchOnce lists a batch of candidate event IDs, then dispatches each
+// one in its own transaction so a single poison-pill event can't wedge the
+// rest of the batch behind it.
+func (d *Dispatcher) dispatchOnce(ctx context.Context) error {
+	var ids []int64
+	err := d.store.ExecTx(ctx, nil, func(q *db.Queries) error {
+		var err error
+		ids, err = q.ListUnpublishedOutboxEventIDs(ctx, d.batchSize)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := d.dispatchEvent(ctx, id); err != nil {
+			log.Printf("outbox dispatcher: event %d: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// dispatchEvent claims a single event row, publishes it, and records the
+// outcome, all inside one transaction. A claim that finds nothing (already
+// published, dead-lettered, or claimed by another dispatcher) is a no-op,
+// not an error. A failed Publish is recorded against the row and committed
+// rather than rolled back, so its attempt count grows until the event is
+// dead-lettered; until then it stays in id order among the events each
+// later tick lists.
+func (d *Dispatcher) dispatchEvent(ctx context.Context, id int64) error {
+	return d.store.ExecTx(ctx, nil, func(q *db.Queries) error {
+		row, err := q.GetOutboxEventForUpdate(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if row.PublishedAt.Valid || row.DeadLetteredAt.Valid {
+			return nil
+		}
+
+		event, err := db.DecodeOutboxEvent(row)
+		if err != nil {
+			return err
+		}
+
+		pubErr := d.publisher.Publish(ctx, event)
+		if pubErr == nil {
+			return q.MarkOutboxEventPublished(ctx, id)
+		}
+
+		lastError := sql.NullString{String: pubErr.Error(), Valid: true}
+		if row.Attempts+1 >= d.maxAttempts {
+			return q.MarkOutboxEventDeadLettered(ctx, db.MarkOutboxEventDeadLetteredParams{ID: id, LastError: lastError})
+		}
+		return q.MarkOutboxEventFailed(ctx, db.MarkOutboxEventFailedParams{ID: id, LastError: lastError})
+	})
+}
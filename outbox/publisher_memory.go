@@ -0,0 +1,35 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+
+	db "simplebank.apiimperfect.com/db/sqlc"
+)
+
+// InMemoryPublisher collects published events in memory. It's meant for
+// tests and local development, not production use.
+type InMemoryPublisher struct {
+	mu     sync.Mutex
+	events []db.OutboxEvent
+}
+
+func NewInMemoryPublisher() *InMemoryPublisher {
+	return &InMemoryPublisher{}
+}
+
+func (p *InMemoryPublisher) Publish(ctx context.Context, event db.OutboxEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+	return nil
+}
+
+// Events returns a copy of every event published so far.
+func (p *InMemoryPublisher) Events() []db.OutboxEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	events := make([]db.OutboxEvent, len(p.events))
+	copy(events, p.events)
+	return events
+}
@@ -0,0 +1,36 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/redis/go-redis/v9"
+
+	db "simplebank.apiimperfect.com/db/sqlc"
+)
+
+// RedisStreamsPublisher appends outbox events to a Redis stream, one entry
+// per event, with the serialized event under the "event" field.
+type RedisStreamsPublisher struct {
+	client *redis.Client
+	stream string
+}
+
+func NewRedisStreamsPublisher(client *redis.Client, stream string) *RedisStreamsPublisher {
+	return &RedisStreamsPublisher{client: client, stream: stream}
+}
+
+func (p *RedisStreamsPublisher) Publish(ctx context.Context, event db.OutboxEvent) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: p.stream,
+		Values: map[string]interface{}{
+			"type":  event.Type,
+			"event": value,
+		},
+	}).Err()
+}